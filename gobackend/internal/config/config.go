@@ -4,6 +4,7 @@ import (
 	"log"
 	"os"
 	"path/filepath"
+	"strconv"
 )
 
 var (
@@ -14,6 +15,19 @@ var (
 	Version   string = "dev" // 版本号，由构建脚本注入，默认为dev
 	// 图片格式配置，默认为webp，可通过环境变量或数据库设置配置为avif
 	ImageFormat string = "webp"
+	// 图片压缩后端配置，支持"local"(本地libvips)、"tinify"(TinyPNG)、"auto"(优先外部，耗尽回退本地)
+	ImageBackend string = "local"
+	// TinyPNG API Key来源: 逗号分隔的Key列表，对应环境变量TINIFY_KEYS
+	TinifyKeys string
+	// TinyPNG API Key文件路径，每行一个Key，对应环境变量TINIFY_KEYS_FILE
+	TinifyKeysFile string
+	// VideoEnabled 是否开启视频上传功能，默认关闭
+	VideoEnabled bool
+	// VideoMaxSizeMB 视频上传大小上限(MB)，默认500
+	VideoMaxSizeMB int = 500
+	// VideoExtensions 受支持的视频上传扩展名，是视频扩展名白名单的唯一来源;
+	// VideoEnabled开启后会被合并进UploadFileExt
+	VideoExtensions = []string{".mp4", ".webm", ".mov"}
 )
 
 // 初始化配置
@@ -66,12 +80,53 @@ func init() {
 		log.Printf("使用默认图片格式: %s", ImageFormat)
 	}
 	
+	// 初始化图片压缩后端配置
+	if backend := os.Getenv("IMAGE_BACKEND"); backend != "" {
+		if backend == "local" || backend == "tinify" || backend == "auto" {
+			ImageBackend = backend
+			log.Printf("使用环境变量指定的图片压缩后端: %s", ImageBackend)
+		} else {
+			log.Printf("环境变量指定的图片压缩后端不支持: %s，使用默认后端: local", backend)
+		}
+	}
+	TinifyKeys = os.Getenv("TINIFY_KEYS")
+	TinifyKeysFile = os.Getenv("TINIFY_KEYS_FILE")
+
+	// 记录动态配置项对应的环境变量原始值，供settings.go按env > DB > default合并
+	envImageFormat = os.Getenv("IMAGE_FORMAT")
+	envImageQuality = os.Getenv("IMAGE_QUALITY")
+	envMaxWidth = os.Getenv("MAX_WIDTH")
+	envMaxHeight = os.Getenv("MAX_HEIGHT")
+	envUploadFileExt = os.Getenv("UPLOAD_FILE_EXT")
+	envResolutionLimit = os.Getenv("RESOLUTION_LIMIT")
+
+	// 初始化视频上传配置
+	if enabled := os.Getenv("VIDEO_ENABLED"); enabled != "" {
+		if parsed, err := strconv.ParseBool(enabled); err == nil {
+			VideoEnabled = parsed
+			log.Printf("使用环境变量指定的视频上传开关: %v", VideoEnabled)
+		} else {
+			log.Printf("环境变量VIDEO_ENABLED值无效: %s，使用默认值: %v", enabled, VideoEnabled)
+		}
+	}
+	if maxSize := os.Getenv("VIDEO_MAX_SIZE_MB"); maxSize != "" {
+		if parsed, err := strconv.Atoi(maxSize); err == nil && parsed > 0 {
+			VideoMaxSizeMB = parsed
+			log.Printf("使用环境变量指定的视频大小上限: %dMB", VideoMaxSizeMB)
+		} else {
+			log.Printf("环境变量VIDEO_MAX_SIZE_MB值无效: %s，使用默认值: %dMB", maxSize, VideoMaxSizeMB)
+		}
+	}
+
 	// 确保目录存在
 	ensureDirExists(filepath.Dir(DbPath))
 	ensureDirExists(AssetsDir)
 	ensureDirExists(filepath.Join(AssetsDir, "uploads"))
 	ensureDirExists(filepath.Join(AssetsDir, "imgs"))
 	ensureDirExists(filepath.Join(AssetsDir, "public"))
+
+	// 打开动态配置存储，并按env > DB > default合并一次
+	initSettingsStore()
 }
 
 // 确保目录存在
@@ -100,6 +155,8 @@ func GetVersion() string {
 // GetImageFormat 获取图片格式配置
 // 返回当前配置的图片格式，支持"webp"和"avif"
 func GetImageFormat() string {
+	settingsMu.RLock()
+	defer settingsMu.RUnlock()
 	return ImageFormat
 }
 
@@ -109,11 +166,39 @@ func GetImageFormat() string {
 // 返回值:
 // - bool: 设置是否成功
 func SetImageFormat(format string) bool {
-	if format == "webp" || format == "avif" {
-		ImageFormat = format
-		log.Printf("图片格式已更新为: %s", ImageFormat)
-		return true
+	if format != "webp" && format != "avif" {
+		log.Printf("无效的图片格式: %s，不支持", format)
+		return false
+	}
+	settingsMu.Lock()
+	ImageFormat = format
+	settingsMu.Unlock()
+	writeThroughSetting(settingsKeyImageFormat, format)
+	log.Printf("图片格式已更新为: %s", format)
+	return true
+}
+
+// GetImageBackend 获取图片压缩后端配置
+// 返回当前配置的压缩后端，"local"/"tinify"/"auto"
+func GetImageBackend() string {
+	settingsMu.RLock()
+	defer settingsMu.RUnlock()
+	return ImageBackend
+}
+
+// SetImageBackend 设置图片压缩后端配置
+// 参数:
+// - backend: 压缩后端，支持"local"、"tinify"、"auto"
+// 返回值:
+// - bool: 设置是否成功
+func SetImageBackend(backend string) bool {
+	if backend != "local" && backend != "tinify" && backend != "auto" {
+		log.Printf("无效的图片压缩后端: %s，不支持", backend)
+		return false
 	}
-	log.Printf("无效的图片格式: %s，不支持", format)
-	return false
+	settingsMu.Lock()
+	ImageBackend = backend
+	settingsMu.Unlock()
+	log.Printf("图片压缩后端已更新为: %s", backend)
+	return true
 }
\ No newline at end of file
@@ -0,0 +1,356 @@
+package config
+
+import (
+	"database/sql"
+	"log"
+	"strconv"
+	"strings"
+	"sync"
+
+	_ "github.com/mattn/go-sqlite3"
+)
+
+// SettingsStore 是配置项的持久化接口，当前由SQLite实现，
+// 供动态可配置项(image_format等)读写。
+type SettingsStore interface {
+	// Get 读取一个配置项，ok为false表示该key在存储中不存在
+	Get(key string) (value string, ok bool, err error)
+	// Set 写入一个配置项
+	Set(key, value string) error
+}
+
+// sqliteSettingsStore 基于resource_hub.db中的settings表实现SettingsStore
+type sqliteSettingsStore struct {
+	db *sql.DB
+}
+
+// newSQLiteSettingsStore 打开(或创建)settings表
+func newSQLiteSettingsStore(dbPath string) (*sqliteSettingsStore, error) {
+	db, err := sql.Open("sqlite3", dbPath)
+	if err != nil {
+		return nil, err
+	}
+
+	if _, err := db.Exec(`CREATE TABLE IF NOT EXISTS settings (
+		key TEXT PRIMARY KEY,
+		value TEXT NOT NULL
+	)`); err != nil {
+		db.Close()
+		return nil, err
+	}
+
+	return &sqliteSettingsStore{db: db}, nil
+}
+
+func (s *sqliteSettingsStore) Get(key string) (string, bool, error) {
+	var value string
+	err := s.db.QueryRow(`SELECT value FROM settings WHERE key = ?`, key).Scan(&value)
+	if err == sql.ErrNoRows {
+		return "", false, nil
+	}
+	if err != nil {
+		return "", false, err
+	}
+	return value, true, nil
+}
+
+func (s *sqliteSettingsStore) Set(key, value string) error {
+	_, err := s.db.Exec(`INSERT INTO settings (key, value) VALUES (?, ?)
+		ON CONFLICT(key) DO UPDATE SET value = excluded.value`, key, value)
+	return err
+}
+
+// 动态配置项的settings表key
+const (
+	settingsKeyImageFormat     = "image_format"
+	settingsKeyImageQuality    = "image_quality"
+	settingsKeyMaxWidth        = "max_width"
+	settingsKeyMaxHeight       = "max_height"
+	settingsKeyUploadFileExt   = "upload_file_ext"
+	settingsKeyResolutionLimit = "resolution_limit"
+)
+
+var (
+	// ImageQuality 图片压缩质量(0-100)，默认80
+	ImageQuality int = 80
+	// MaxWidth 转换输出的最大宽度，0表示不限制
+	MaxWidth int
+	// MaxHeight 转换输出的最大高度，0表示不限制
+	MaxHeight int
+	// UploadFileExt 允许上传的文件扩展名(含点号，逗号分隔)
+	UploadFileExt string = ".jpg,.jpeg,.png,.webp,.gif"
+	// ResolutionLimit 解码图片允许的最大分辨率(单位: 百万像素)，默认100
+	ResolutionLimit float64 = 100
+
+	settingsStore SettingsStore
+	settingsMu    sync.RWMutex
+
+	subscribersMu sync.Mutex
+	subscribers   []chan struct{}
+
+	// 动态配置项对应环境变量的原始值，由config.go的init()填充
+	envImageFormat     string
+	envImageQuality    string
+	envMaxWidth        string
+	envMaxHeight       string
+	envUploadFileExt   string
+	envResolutionLimit string
+)
+
+// initSettingsStore 打开settings存储，并按env > DB > default的优先级合并一次配置。
+// 存储打开失败时记录日志并继续使用env/default，不阻塞启动。
+func initSettingsStore() {
+	store, err := newSQLiteSettingsStore(DbPath)
+	if err != nil {
+		log.Printf("打开设置存储失败，动态配置将不可用: %v", err)
+	} else {
+		settingsStore = store
+	}
+	if err := loadSettings(); err != nil {
+		log.Printf("加载动态配置失败: %v", err)
+	}
+
+	applyVideoWhitelist()
+}
+
+// applyVideoWhitelist 在VideoEnabled开启时，将视频扩展名注册进上传白名单(幂等)
+func applyVideoWhitelist() {
+	settingsMu.Lock()
+	defer settingsMu.Unlock()
+
+	if !VideoEnabled {
+		return
+	}
+
+	existing := strings.Split(UploadFileExt, ",")
+	seen := make(map[string]bool, len(existing))
+	for _, e := range existing {
+		seen[strings.TrimSpace(e)] = true
+	}
+
+	changed := false
+	for _, ext := range VideoExtensions {
+		if !seen[ext] {
+			existing = append(existing, ext)
+			changed = true
+		}
+	}
+
+	if changed {
+		UploadFileExt = strings.Join(existing, ",")
+	}
+}
+
+// loadSettings 按env > DB > default的优先级合并一次动态配置项
+func loadSettings() error {
+	settingsMu.Lock()
+	defer settingsMu.Unlock()
+
+	if v, ok := mergeString(settingsKeyImageFormat, envImageFormat); ok {
+		if v == "webp" || v == "avif" {
+			ImageFormat = v
+		}
+	}
+	if v, ok := mergeInt(settingsKeyImageQuality, envImageQuality); ok {
+		ImageQuality = v
+	}
+	if v, ok := mergeInt(settingsKeyMaxWidth, envMaxWidth); ok {
+		MaxWidth = v
+	}
+	if v, ok := mergeInt(settingsKeyMaxHeight, envMaxHeight); ok {
+		MaxHeight = v
+	}
+	if v, ok := mergeString(settingsKeyUploadFileExt, envUploadFileExt); ok {
+		UploadFileExt = v
+	}
+	if v, ok := mergeFloat(settingsKeyResolutionLimit, envResolutionLimit); ok {
+		ResolutionLimit = v
+	}
+
+	return nil
+}
+
+// mergeString 按env > DB的优先级解析字符串配置项，DB中不存在且env为空时返回ok=false(沿用默认值)
+func mergeString(key, envValue string) (string, bool) {
+	if envValue != "" {
+		return envValue, true
+	}
+	if settingsStore == nil {
+		return "", false
+	}
+	if v, ok, err := settingsStore.Get(key); err == nil && ok {
+		return v, true
+	}
+	return "", false
+}
+
+func mergeInt(key, envValue string) (int, bool) {
+	raw, ok := mergeString(key, envValue)
+	if !ok {
+		return 0, false
+	}
+	v, err := strconv.Atoi(strings.TrimSpace(raw))
+	if err != nil {
+		return 0, false
+	}
+	return v, true
+}
+
+func mergeFloat(key, envValue string) (float64, bool) {
+	raw, ok := mergeString(key, envValue)
+	if !ok {
+		return 0, false
+	}
+	v, err := strconv.ParseFloat(strings.TrimSpace(raw), 64)
+	if err != nil {
+		return 0, false
+	}
+	return v, true
+}
+
+// Reload 重新从env/DB合并一次动态配置，并通知所有订阅者
+func Reload() error {
+	if err := loadSettings(); err != nil {
+		return err
+	}
+	applyVideoWhitelist()
+	notifySubscribers()
+	return nil
+}
+
+// Subscribe 注册一个配置变更通知channel，每次Reload()后都会发送一次通知；
+// channel缓冲为1，订阅方未及时读取时，期间的多次通知会合并为一次。
+// 供编码worker、上传处理器等长生命周期的goroutine监听配置热更新。
+func Subscribe() <-chan struct{} {
+	ch := make(chan struct{}, 1)
+	subscribersMu.Lock()
+	subscribers = append(subscribers, ch)
+	subscribersMu.Unlock()
+	return ch
+}
+
+func notifySubscribers() {
+	subscribersMu.Lock()
+	defer subscribersMu.Unlock()
+	for _, ch := range subscribers {
+		select {
+		case ch <- struct{}{}:
+		default:
+		}
+	}
+}
+
+// GetImageQuality 获取图片压缩质量配置(0-100)
+func GetImageQuality() int {
+	settingsMu.RLock()
+	defer settingsMu.RUnlock()
+	return ImageQuality
+}
+
+// SetImageQuality 设置图片压缩质量配置并写入DB持久化
+func SetImageQuality(quality int) bool {
+	if quality < 0 || quality > 100 {
+		log.Printf("无效的图片压缩质量: %d，不支持", quality)
+		return false
+	}
+	settingsMu.Lock()
+	ImageQuality = quality
+	settingsMu.Unlock()
+	writeThroughSetting(settingsKeyImageQuality, strconv.Itoa(quality))
+	log.Printf("图片压缩质量已更新为: %d", quality)
+	return true
+}
+
+// GetMaxWidth 获取转换输出的最大宽度，0表示不限制
+func GetMaxWidth() int {
+	settingsMu.RLock()
+	defer settingsMu.RUnlock()
+	return MaxWidth
+}
+
+// SetMaxWidth 设置转换输出的最大宽度并写入DB持久化
+func SetMaxWidth(width int) bool {
+	if width < 0 {
+		log.Printf("无效的最大宽度: %d，不支持", width)
+		return false
+	}
+	settingsMu.Lock()
+	MaxWidth = width
+	settingsMu.Unlock()
+	writeThroughSetting(settingsKeyMaxWidth, strconv.Itoa(width))
+	log.Printf("最大宽度已更新为: %d", width)
+	return true
+}
+
+// GetMaxHeight 获取转换输出的最大高度，0表示不限制
+func GetMaxHeight() int {
+	settingsMu.RLock()
+	defer settingsMu.RUnlock()
+	return MaxHeight
+}
+
+// SetMaxHeight 设置转换输出的最大高度并写入DB持久化
+func SetMaxHeight(height int) bool {
+	if height < 0 {
+		log.Printf("无效的最大高度: %d，不支持", height)
+		return false
+	}
+	settingsMu.Lock()
+	MaxHeight = height
+	settingsMu.Unlock()
+	writeThroughSetting(settingsKeyMaxHeight, strconv.Itoa(height))
+	log.Printf("最大高度已更新为: %d", height)
+	return true
+}
+
+// GetUploadFileExt 获取允许上传的文件扩展名列表(逗号分隔，含点号)
+func GetUploadFileExt() string {
+	settingsMu.RLock()
+	defer settingsMu.RUnlock()
+	return UploadFileExt
+}
+
+// SetUploadFileExt 设置允许上传的文件扩展名列表并写入DB持久化
+func SetUploadFileExt(ext string) bool {
+	if strings.TrimSpace(ext) == "" {
+		log.Printf("无效的上传文件扩展名白名单: 不能为空")
+		return false
+	}
+	settingsMu.Lock()
+	UploadFileExt = ext
+	settingsMu.Unlock()
+	writeThroughSetting(settingsKeyUploadFileExt, ext)
+	log.Printf("上传文件扩展名白名单已更新为: %s", ext)
+	return true
+}
+
+// GetResolutionLimit 获取解码图片允许的最大分辨率(单位: 百万像素)
+func GetResolutionLimit() float64 {
+	settingsMu.RLock()
+	defer settingsMu.RUnlock()
+	return ResolutionLimit
+}
+
+// SetResolutionLimit 设置解码图片允许的最大分辨率并写入DB持久化
+func SetResolutionLimit(megapixels float64) bool {
+	if megapixels <= 0 {
+		log.Printf("无效的分辨率上限: %f，不支持", megapixels)
+		return false
+	}
+	settingsMu.Lock()
+	ResolutionLimit = megapixels
+	settingsMu.Unlock()
+	writeThroughSetting(settingsKeyResolutionLimit, strconv.FormatFloat(megapixels, 'f', -1, 64))
+	log.Printf("分辨率上限已更新为: %.2f百万像素", megapixels)
+	return true
+}
+
+// writeThroughSetting 将一个动态配置项写入DB；存储未初始化时静默跳过(仅内存生效)
+func writeThroughSetting(key, value string) {
+	if settingsStore == nil {
+		return
+	}
+	if err := settingsStore.Set(key, value); err != nil {
+		log.Printf("写入配置项到数据库失败 %s=%s: %v", key, value, err)
+	}
+}
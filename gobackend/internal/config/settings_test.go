@@ -0,0 +1,144 @@
+package config
+
+import "testing"
+
+// fakeSettingsStore 是SettingsStore的内存实现，仅用于测试env > DB > default的合并逻辑
+type fakeSettingsStore struct {
+	values map[string]string
+}
+
+func newFakeSettingsStore(values map[string]string) *fakeSettingsStore {
+	return &fakeSettingsStore{values: values}
+}
+
+func (f *fakeSettingsStore) Get(key string) (string, bool, error) {
+	v, ok := f.values[key]
+	return v, ok, nil
+}
+
+func (f *fakeSettingsStore) Set(key, value string) error {
+	f.values[key] = value
+	return nil
+}
+
+func withSettingsStore(t *testing.T, store SettingsStore, fn func()) {
+	t.Helper()
+	prev := settingsStore
+	settingsStore = store
+	defer func() { settingsStore = prev }()
+	fn()
+}
+
+func TestMergeStringEnvTakesPriorityOverDB(t *testing.T) {
+	withSettingsStore(t, newFakeSettingsStore(map[string]string{"k": "from-db"}), func() {
+		v, ok := mergeString("k", "from-env")
+		if !ok || v != "from-env" {
+			t.Errorf("got (%q, %v), want (\"from-env\", true)", v, ok)
+		}
+	})
+}
+
+func TestMergeStringFallsBackToDB(t *testing.T) {
+	withSettingsStore(t, newFakeSettingsStore(map[string]string{"k": "from-db"}), func() {
+		v, ok := mergeString("k", "")
+		if !ok || v != "from-db" {
+			t.Errorf("got (%q, %v), want (\"from-db\", true)", v, ok)
+		}
+	})
+}
+
+func TestMergeStringNeitherPresentKeepsDefault(t *testing.T) {
+	withSettingsStore(t, newFakeSettingsStore(map[string]string{}), func() {
+		v, ok := mergeString("k", "")
+		if ok {
+			t.Errorf("got (%q, %v), want ok=false", v, ok)
+		}
+	})
+}
+
+func TestMergeIntParsesValue(t *testing.T) {
+	withSettingsStore(t, newFakeSettingsStore(map[string]string{"k": "42"}), func() {
+		v, ok := mergeInt("k", "")
+		if !ok || v != 42 {
+			t.Errorf("got (%d, %v), want (42, true)", v, ok)
+		}
+	})
+}
+
+func TestMergeIntInvalidValueReturnsNotOK(t *testing.T) {
+	withSettingsStore(t, newFakeSettingsStore(map[string]string{"k": "not-a-number"}), func() {
+		if _, ok := mergeInt("k", ""); ok {
+			t.Errorf("expected ok=false for invalid int value")
+		}
+	})
+}
+
+func TestMergeFloatParsesEnvValue(t *testing.T) {
+	withSettingsStore(t, newFakeSettingsStore(map[string]string{}), func() {
+		v, ok := mergeFloat("k", "12.5")
+		if !ok || v != 12.5 {
+			t.Errorf("got (%v, %v), want (12.5, true)", v, ok)
+		}
+	})
+}
+
+func TestLoadSettingsMergesEnvOverDBAndKeepsDefaultWhenAbsent(t *testing.T) {
+	origImageFormat, origImageQuality := ImageFormat, ImageQuality
+	origMaxWidth, origMaxHeight := MaxWidth, MaxHeight
+	origUploadFileExt, origResolutionLimit := UploadFileExt, ResolutionLimit
+	origEnvImageFormat, origEnvImageQuality := envImageFormat, envImageQuality
+	origEnvMaxWidth, origEnvMaxHeight := envMaxWidth, envMaxHeight
+	origEnvUploadFileExt, origEnvResolutionLimit := envUploadFileExt, envResolutionLimit
+	defer func() {
+		ImageFormat, ImageQuality = origImageFormat, origImageQuality
+		MaxWidth, MaxHeight = origMaxWidth, origMaxHeight
+		UploadFileExt, ResolutionLimit = origUploadFileExt, origResolutionLimit
+		envImageFormat, envImageQuality = origEnvImageFormat, origEnvImageQuality
+		envMaxWidth, envMaxHeight = origEnvMaxWidth, origEnvMaxHeight
+		envUploadFileExt, envResolutionLimit = origEnvUploadFileExt, origEnvResolutionLimit
+	}()
+
+	// env优先于DB
+	envImageFormat = "avif"
+	envImageQuality = ""
+	envMaxWidth = ""
+	envMaxHeight = ""
+	envUploadFileExt = ""
+	envResolutionLimit = ""
+
+	store := newFakeSettingsStore(map[string]string{
+		settingsKeyImageFormat:  "webp", // 应被env覆盖
+		settingsKeyImageQuality: "55",   // env为空，DB生效
+		settingsKeyMaxWidth:     "800",  // env为空，DB生效
+		// settingsKeyMaxHeight、settingsKeyUploadFileExt、settingsKeyResolutionLimit均不存在，应保留默认值
+	})
+
+	MaxHeight = 999
+	UploadFileExt = "default-ext"
+	ResolutionLimit = 77
+
+	withSettingsStore(t, store, func() {
+		if err := loadSettings(); err != nil {
+			t.Fatalf("loadSettings returned error: %v", err)
+		}
+	})
+
+	if ImageFormat != "avif" {
+		t.Errorf("ImageFormat: got %q, want avif (env should win over DB)", ImageFormat)
+	}
+	if ImageQuality != 55 {
+		t.Errorf("ImageQuality: got %d, want 55 (from DB)", ImageQuality)
+	}
+	if MaxWidth != 800 {
+		t.Errorf("MaxWidth: got %d, want 800 (from DB)", MaxWidth)
+	}
+	if MaxHeight != 999 {
+		t.Errorf("MaxHeight: got %d, want 999 (default kept, neither env nor DB present)", MaxHeight)
+	}
+	if UploadFileExt != "default-ext" {
+		t.Errorf("UploadFileExt: got %q, want default-ext (default kept)", UploadFileExt)
+	}
+	if ResolutionLimit != 77 {
+		t.Errorf("ResolutionLimit: got %v, want 77 (default kept)", ResolutionLimit)
+	}
+}
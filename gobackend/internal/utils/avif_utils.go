@@ -10,7 +10,8 @@ import (
 	"strings"
 	"sync"
 	"time"
-	// 临时注释AVIF支持，使用标准库处理其他格式
+
+	"github.com/aspnmy/GoComicMosaic-gobackend/internal/utils/imgenc"
 )
 
 // ConvertToAvif 将图片转换为AVIF格式
@@ -22,8 +23,7 @@ import (
 // - 输出图片路径
 // - 错误信息
 func ConvertToAvif(imgPath string, useAvifExt bool, quality int) (string, error) {
-	// 临时禁用AVIF支持，返回错误信息
-	return "", fmt.Errorf("AVIF支持暂时不可用，请稍后再试")
+	return ConvertToAvifWithRatio(imgPath, 0, 0, true, useAvifExt, quality)
 }
 
 // ConvertToAvifWithRatio 将图片转换为AVIF格式并保持原始宽高比
@@ -38,8 +38,31 @@ func ConvertToAvif(imgPath string, useAvifExt bool, quality int) (string, error)
 // - 输出图片路径
 // - 错误信息
 func ConvertToAvifWithRatio(imgPath string, maxWidth, maxHeight int, keepOriginal, useAvifExt bool, quality int) (string, error) {
-	// 临时禁用AVIF支持，返回错误信息
-	return "", fmt.Errorf("AVIF支持暂时不可用，请稍后再试")
+	return ConvertToAvifWithMode(imgPath, maxWidth, maxHeight, keepOriginal, useAvifExt, quality, imgenc.ResizeFit)
+}
+
+// ConvertToAvifWithMode 将图片转换为AVIF格式，并允许调用方按请求选择缩放策略
+// 参数:
+// - imgPath: 输入图片路径
+// - maxWidth: 最大宽度(0表示自动判断)
+// - maxHeight: 最大高度(0表示自动判断)
+// - keepOriginal: 是否保留原始图片
+// - useAvifExt: 是否使用.avif扩展名
+// - quality: AVIF压缩质量(0-100)
+// - mode: 缩放策略(ResizeFit/ResizeFill/ResizeSmartCrop)，仅在maxWidth和maxHeight都提供时生效
+// 返回值:
+// - 输出图片路径
+// - 错误信息
+func ConvertToAvifWithMode(imgPath string, maxWidth, maxHeight int, keepOriginal, useAvifExt bool, quality int, mode imgenc.ResizeMode) (string, error) {
+	return convertWithBackend(imgPath, imgenc.EncodeOptions{
+		Format:       imgenc.FormatAVIF,
+		Quality:      quality,
+		MaxWidth:     maxWidth,
+		MaxHeight:    maxHeight,
+		Mode:         mode,
+		KeepOriginal: keepOriginal,
+		UseTargetExt: useAvifExt,
+	})
 }
 
 // ConvertMultipleImagesToAvif 处理JSON列表中的多张图片，转换为AVIF格式
@@ -81,7 +104,7 @@ func ConvertMultipleImagesToAvif(jsonList string, keepOriginal, useAvifExt bool,
 			defer wg.Done()
 			for imgPath := range jobs {
 				// 尝试转换图片
-				outputPath, err := ConvertToAvif(imgPath, useAvifExt, quality)
+				outputPath, err := ConvertToAvifWithRatio(imgPath, 0, 0, keepOriginal, useAvifExt, quality)
 				if err != nil {
 					errs <- fmt.Errorf("处理 %s 失败: %w", imgPath, err)
 				} else {
@@ -151,8 +174,18 @@ func ProcessDirectoryToAvifSync(dirPath string, recursive bool, keepOriginal, us
 		ext := strings.ToLower(filepath.Ext(path))
 		switch ext {
 		case ".jpg", ".jpeg", ".png", ".webp":
+			// 分辨率守卫: 超限文件跳过而不是送入解码器，避免解压缩炸弹拖垮worker
+			if _, _, resErr := CheckImageResolution(path); resErr != nil {
+				if errors.Is(resErr, ErrResolutionExceeded) {
+					log.Printf("跳过超出分辨率上限的图片 %s: %v", path, resErr)
+				} else {
+					log.Printf("检查图片分辨率失败 %s: %v", path, resErr)
+				}
+				return nil
+			}
+
 			// 处理图片
-			_, err = ConvertToAvif(path, useAvifExt, quality)
+			_, err = ConvertToAvifWithRatio(path, 0, 0, keepOriginal, useAvifExt, quality)
 			if err != nil {
 				log.Printf("处理 %s 失败: %v", path, err)
 				return nil // 继续处理其他文件
@@ -223,6 +256,15 @@ func BatchProcessImagesToAvif(dirPath string, recursive bool, keepOriginal, useA
 		ext := strings.ToLower(filepath.Ext(path))
 		switch ext {
 		case ".jpg", ".jpeg", ".png", ".webp":
+			// 分辨率守卫: 超限文件跳过而不是送入解码器，避免解压缩炸弹拖垮worker
+			if _, _, resErr := CheckImageResolution(path); resErr != nil {
+				if errors.Is(resErr, ErrResolutionExceeded) {
+					log.Printf("跳过超出分辨率上限的图片 %s: %v", path, resErr)
+				} else {
+					log.Printf("检查图片分辨率失败 %s: %v", path, resErr)
+				}
+				return nil
+			}
 			imgPaths = append(imgPaths, path)
 		}
 		return nil
@@ -280,7 +322,7 @@ func BatchProcessImagesToAvif(dirPath string, recursive bool, keepOriginal, useA
 			defer wg.Done()
 			for imgPath := range jobs {
 				// 处理图片
-				_, err := ConvertToAvif(imgPath, useAvifExt, quality)
+				_, err := ConvertToAvifWithRatio(imgPath, 0, 0, keepOriginal, useAvifExt, quality)
 				if err != nil {
 					log.Printf("处理 %s 失败: %v", imgPath, err)
 					continue
@@ -306,44 +348,6 @@ func BatchProcessImagesToAvif(dirPath string, recursive bool, keepOriginal, useA
 	return count, nil
 }
 
-// 辅助函数: 计算目标尺寸，保持宽高比
-func calculateTargetSize(originalWidth, originalHeight, maxWidth, maxHeight int) (int, int) {
-	// 如果未指定最大尺寸，则使用默认值
-	if maxWidth <= 0 || maxHeight <= 0 {
-		// 根据图片方向设置默认尺寸
-		if originalWidth > originalHeight {
-			// 横图
-			maxWidth = 1280
-			maxHeight = 720
-		} else {
-			// 竖图
-			maxWidth = 600
-			maxHeight = 900
-		}
-	}
-
-	// 计算缩放比例
-	widthRatio := float64(maxWidth) / float64(originalWidth)
-	heightRatio := float64(maxHeight) / float64(originalHeight)
-
-	// 使用较小的缩放比例，以确保图片完全适应目标尺寸
-	scaleRatio := widthRatio
-	if heightRatio < widthRatio {
-		scaleRatio = heightRatio
-	}
-
-	// 如果图片已经小于目标尺寸，则保持原始尺寸
-	if scaleRatio > 1.0 {
-		scaleRatio = 1.0
-	}
-
-	// 计算新的尺寸
-	newWidth := int(float64(originalWidth) * scaleRatio)
-	newHeight := int(float64(originalHeight) * scaleRatio)
-
-	return newWidth, newHeight
-}
-
 // 辅助函数: 获取图片类型
 func getImageType(path string) string {
 	ext := strings.ToLower(filepath.Ext(path))
@@ -0,0 +1,66 @@
+package utils
+
+import (
+	"fmt"
+	"log"
+	"os"
+	"sync"
+
+	"github.com/aspnmy/GoComicMosaic-gobackend/internal/config"
+	"github.com/aspnmy/GoComicMosaic-gobackend/internal/utils/backends"
+	"github.com/aspnmy/GoComicMosaic-gobackend/internal/utils/imgenc"
+)
+
+var (
+	tinifyBackend     *backends.TinyPNGBackend
+	tinifyBackendOnce sync.Once
+)
+
+// getTinifyBackend 惰性初始化TinyPNG后端及其API Key池
+func getTinifyBackend() *backends.TinyPNGBackend {
+	tinifyBackendOnce.Do(func() {
+		keys := backends.LoadKeys(config.TinifyKeys, config.TinifyKeysFile)
+		tinifyBackend = backends.NewTinyPNGBackend(backends.NewKeyPool(keys))
+	})
+	return tinifyBackend
+}
+
+// convertWithBackend 根据config.ImageBackend在外部压缩服务与本地libvips编码之间选择。
+// "local"只使用本地编码；"tinify"/"auto"会先把源文件提交给外部服务做体积压缩(Key耗尽
+// 或请求失败时跳过这一步)，但格式转换、缩放/裁剪(opts.Format/Mode/MaxWidth/MaxHeight)
+// 始终由本地libvips编码器按opts完整执行一遍，确保返回结果就是调用方请求的格式与尺寸。
+//
+// 这是utils包内所有图片转换(单张上传、批量目录、视频封面)共同的入口，因此分辨率
+// 守卫在这里统一拦截，而不是依赖各个调用方自行检查。
+func convertWithBackend(srcPath string, opts imgenc.EncodeOptions) (string, error) {
+	if _, _, err := CheckImageResolution(srcPath); err != nil {
+		return "", fmt.Errorf("分辨率检查未通过 %s: %w", srcPath, err)
+	}
+
+	if config.GetImageBackend() != "local" {
+		preCompress(srcPath)
+	}
+
+	return imgenc.ConvertImage(srcPath, opts)
+}
+
+// preCompress 在本地编码前，先把源文件提交给外部压缩服务做一次体积压缩(原地覆盖)。
+// 失败或Key耗尽时只记录日志并继续，不影响后续的本地编码。
+func preCompress(srcPath string) {
+	data, err := os.ReadFile(srcPath)
+	if err != nil {
+		log.Printf("读取源文件失败，跳过外部压缩 %s: %v", srcPath, err)
+		return
+	}
+
+	tp := getTinifyBackend()
+	compressed, err := tp.Compress(data)
+	if err != nil {
+		log.Printf("外部压缩后端(%s)不可用，跳过外部压缩直接本地编码 %s: %v", tp.Name(), srcPath, err)
+		return
+	}
+
+	if err := os.WriteFile(srcPath, compressed, 0644); err != nil {
+		log.Printf("写入外部压缩结果失败，跳过外部压缩直接本地编码 %s: %v", srcPath, err)
+	}
+}
@@ -0,0 +1,16 @@
+// Package backends 定义可插拔的图片压缩后端抽象，
+// 使图片转换除本地libvips编码外，也可以委托给外部压缩服务。
+package backends
+
+import "errors"
+
+// ErrAllKeysExhausted 表示密钥池中所有API Key当月额度都已用尽
+var ErrAllKeysExhausted = errors.New("所有API Key本月额度均已用尽")
+
+// Backend 是一个可插拔的图片压缩后端
+type Backend interface {
+	// Name 返回后端名称，用于日志
+	Name() string
+	// Compress 压缩图片数据，返回压缩后的结果
+	Compress(data []byte) ([]byte, error)
+}
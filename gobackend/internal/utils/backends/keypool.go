@@ -0,0 +1,134 @@
+package backends
+
+import (
+	"bufio"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// apiKey 记录单个API Key在当月的使用情况
+type apiKey struct {
+	key       string
+	month     string // 格式: "2006-01"，用于按月重置额度
+	usedCount int
+	exhausted bool
+}
+
+// KeyPool 管理一组API Key，按月跟踪用量并在轮询中跳过已耗尽的Key
+type KeyPool struct {
+	mu   sync.Mutex
+	keys []*apiKey
+	next int
+}
+
+// NewKeyPool 使用给定的Key列表创建一个密钥池
+func NewKeyPool(keys []string) *KeyPool {
+	pool := &KeyPool{}
+	for _, k := range keys {
+		k = strings.TrimSpace(k)
+		if k == "" {
+			continue
+		}
+		pool.keys = append(pool.keys, &apiKey{key: k, month: currentMonth()})
+	}
+	return pool
+}
+
+// LoadKeys 从环境变量(逗号分隔)和/或密钥文件(每行一个)加载API Key，两者会被合并
+// 参数:
+// - envValue: 环境变量TINIFY_KEYS的值
+// - keysFilePath: 密钥文件路径，为空则跳过
+// 返回值:
+// - 去重后的Key列表
+func LoadKeys(envValue string, keysFilePath string) []string {
+	seen := make(map[string]bool)
+	var keys []string
+
+	for _, k := range strings.Split(envValue, ",") {
+		k = strings.TrimSpace(k)
+		if k != "" && !seen[k] {
+			seen[k] = true
+			keys = append(keys, k)
+		}
+	}
+
+	if keysFilePath != "" {
+		if f, err := os.Open(keysFilePath); err == nil {
+			defer f.Close()
+			scanner := bufio.NewScanner(f)
+			for scanner.Scan() {
+				k := strings.TrimSpace(scanner.Text())
+				if k != "" && !seen[k] {
+					seen[k] = true
+					keys = append(keys, k)
+				}
+			}
+		}
+	}
+
+	return keys
+}
+
+// Next 轮询返回下一个未耗尽的Key
+func (p *KeyPool) Next() (string, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	n := len(p.keys)
+	if n == 0 {
+		return "", ErrAllKeysExhausted
+	}
+
+	month := currentMonth()
+	for i := 0; i < n; i++ {
+		idx := (p.next + i) % n
+		k := p.keys[idx]
+		if k.month != month {
+			k.month = month
+			k.usedCount = 0
+			k.exhausted = false
+		}
+		if !k.exhausted {
+			p.next = (idx + 1) % n
+			return k.key, nil
+		}
+	}
+
+	return "", ErrAllKeysExhausted
+}
+
+// RecordUsage 根据TinyPNG返回的Compression-Count响应头更新Key的当月用量
+func (p *KeyPool) RecordUsage(key string, compressionCount string) {
+	count, err := strconv.Atoi(strings.TrimSpace(compressionCount))
+	if err != nil {
+		return
+	}
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	for _, k := range p.keys {
+		if k.key == key {
+			k.usedCount = count
+			return
+		}
+	}
+}
+
+// MarkExhausted 将Key标记为本月额度已耗尽
+func (p *KeyPool) MarkExhausted(key string) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	for _, k := range p.keys {
+		if k.key == key {
+			k.exhausted = true
+			return
+		}
+	}
+}
+
+func currentMonth() string {
+	return time.Now().Format("2006-01")
+}
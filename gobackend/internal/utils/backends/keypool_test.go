@@ -0,0 +1,115 @@
+package backends
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestKeyPoolNextRoundRobin(t *testing.T) {
+	pool := NewKeyPool([]string{"k1", "k2", "k3"})
+
+	for i, want := range []string{"k1", "k2", "k3", "k1"} {
+		got, err := pool.Next()
+		if err != nil {
+			t.Fatalf("call %d: unexpected error: %v", i, err)
+		}
+		if got != want {
+			t.Errorf("call %d: got %s, want %s", i, got, want)
+		}
+	}
+}
+
+func TestKeyPoolNextSkipsExhausted(t *testing.T) {
+	pool := NewKeyPool([]string{"k1", "k2", "k3"})
+	pool.MarkExhausted("k2")
+
+	for i, want := range []string{"k1", "k3", "k1", "k3"} {
+		got, err := pool.Next()
+		if err != nil {
+			t.Fatalf("call %d: unexpected error: %v", i, err)
+		}
+		if got != want {
+			t.Errorf("call %d: got %s, want %s", i, got, want)
+		}
+	}
+}
+
+func TestKeyPoolNextAllExhaustedReturnsError(t *testing.T) {
+	pool := NewKeyPool([]string{"k1", "k2"})
+	pool.MarkExhausted("k1")
+	pool.MarkExhausted("k2")
+
+	if _, err := pool.Next(); !errors.Is(err, ErrAllKeysExhausted) {
+		t.Fatalf("got err=%v, want ErrAllKeysExhausted", err)
+	}
+}
+
+func TestKeyPoolNextEmptyPoolReturnsError(t *testing.T) {
+	pool := NewKeyPool(nil)
+
+	if _, err := pool.Next(); !errors.Is(err, ErrAllKeysExhausted) {
+		t.Fatalf("got err=%v, want ErrAllKeysExhausted", err)
+	}
+}
+
+func TestKeyPoolMonthRolloverResetsExhausted(t *testing.T) {
+	pool := NewKeyPool([]string{"k1"})
+	pool.MarkExhausted("k1")
+	pool.RecordUsage("k1", "500")
+
+	// 模拟跨月: 把key的month字段回退到一个过去的月份
+	pool.keys[0].month = "2000-01"
+
+	got, err := pool.Next()
+	if err != nil {
+		t.Fatalf("expected key to become available after month rollover, got err=%v", err)
+	}
+	if got != "k1" {
+		t.Errorf("got %s, want k1", got)
+	}
+	if pool.keys[0].exhausted {
+		t.Errorf("expected exhausted to be reset after month rollover")
+	}
+	if pool.keys[0].usedCount != 0 {
+		t.Errorf("expected usedCount to be reset after month rollover, got %d", pool.keys[0].usedCount)
+	}
+}
+
+func TestKeyPoolRecordUsage(t *testing.T) {
+	pool := NewKeyPool([]string{"k1"})
+	pool.RecordUsage("k1", "42")
+
+	if pool.keys[0].usedCount != 42 {
+		t.Errorf("got usedCount=%d, want 42", pool.keys[0].usedCount)
+	}
+}
+
+func TestLoadKeysDedupesAcrossEnvAndFile(t *testing.T) {
+	dir := t.TempDir()
+	keysFile := filepath.Join(dir, "keys.txt")
+	if err := os.WriteFile(keysFile, []byte("k2\nk3\n\nk1\n"), 0644); err != nil {
+		t.Fatalf("failed to write keys file: %v", err)
+	}
+
+	got := LoadKeys("k1, k2", keysFile)
+	want := []string{"k1", "k2", "k3"}
+
+	if len(got) != len(want) {
+		t.Fatalf("got %v, want %v", got, want)
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Errorf("got %v, want %v", got, want)
+			break
+		}
+	}
+}
+
+func TestLoadKeysEmpty(t *testing.T) {
+	got := LoadKeys("", "")
+	if len(got) != 0 {
+		t.Errorf("got %v, want empty", got)
+	}
+}
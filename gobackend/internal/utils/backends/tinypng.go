@@ -0,0 +1,110 @@
+package backends
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"time"
+)
+
+const tinifyShrinkURL = "https://api.tinify.com/shrink"
+
+// defaultHTTPTimeout 单次TinyPNG请求(提交/下载)允许的最长耗时，避免卡死调用的goroutine
+const defaultHTTPTimeout = 30 * time.Second
+
+// errKeyRateLimited 表示某个Key本次请求被TinyPNG限流(HTTP 429)，应换下一个Key重试，
+// 与ErrAllKeysExhausted(整个池都耗尽)区分开
+var errKeyRateLimited = errors.New("该Key已触发限流")
+
+// TinyPNGBackend 通过TinyPNG的/shrink接口压缩图片，并在一组API Key间轮询
+type TinyPNGBackend struct {
+	keys   *KeyPool
+	client *http.Client
+}
+
+// NewTinyPNGBackend 使用给定的密钥池创建一个TinyPNG后端
+func NewTinyPNGBackend(keys *KeyPool) *TinyPNGBackend {
+	return &TinyPNGBackend{
+		keys:   keys,
+		client: &http.Client{Timeout: defaultHTTPTimeout},
+	}
+}
+
+// Name 返回后端名称
+func (b *TinyPNGBackend) Name() string {
+	return "tinypng"
+}
+
+// Compress 将图片提交给TinyPNG压缩，自动在密钥池中轮询可用Key。
+// 单个Key被限流时会换下一个Key重试，直到有Key成功或整个池当月额度均已用尽
+// (此时返回ErrAllKeysExhausted，调用方应回退到本地编码)。
+// 限流以外的请求错误(网络错误、其他异常状态码)会直接返回，不再尝试其他Key。
+func (b *TinyPNGBackend) Compress(data []byte) ([]byte, error) {
+	for {
+		key, err := b.keys.Next()
+		if err != nil {
+			return nil, err
+		}
+
+		location, err := b.shrink(key, data)
+		if errors.Is(err, errKeyRateLimited) {
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		return b.fetch(location)
+	}
+}
+
+// shrink 提交源图片字节，返回压缩结果的下载地址(Location响应头)
+func (b *TinyPNGBackend) shrink(key string, data []byte) (string, error) {
+	req, err := http.NewRequest(http.MethodPost, tinifyShrinkURL, bytes.NewReader(data))
+	if err != nil {
+		return "", fmt.Errorf("构造TinyPNG请求失败: %w", err)
+	}
+	req.SetBasicAuth("api", key)
+
+	resp, err := b.client.Do(req)
+	if err != nil {
+		return "", fmt.Errorf("请求TinyPNG失败: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode == http.StatusTooManyRequests {
+		b.keys.MarkExhausted(key)
+		return "", errKeyRateLimited
+	}
+
+	if resp.StatusCode != http.StatusCreated {
+		body, _ := io.ReadAll(resp.Body)
+		return "", fmt.Errorf("TinyPNG返回异常状态码 %d: %s", resp.StatusCode, body)
+	}
+
+	b.keys.RecordUsage(key, resp.Header.Get("Compression-Count"))
+
+	location := resp.Header.Get("Location")
+	if location == "" {
+		return "", fmt.Errorf("TinyPNG响应缺少Location头")
+	}
+	return location, nil
+}
+
+// fetch 下载压缩后的图片结果
+func (b *TinyPNGBackend) fetch(location string) ([]byte, error) {
+	resp, err := b.client.Get(location)
+	if err != nil {
+		return nil, fmt.Errorf("下载压缩结果失败: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		body, _ := io.ReadAll(resp.Body)
+		return nil, fmt.Errorf("下载压缩结果返回异常状态码 %d: %s", resp.StatusCode, body)
+	}
+
+	return io.ReadAll(resp.Body)
+}
@@ -0,0 +1,213 @@
+// Package imgenc 提供基于libvips的统一图片编码管线。
+// 所有格式转换(AVIF/WebP)都应通过本包的ConvertImage入口完成，
+// 避免重复解码同一张源图。
+package imgenc
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+
+	"github.com/davidbyttow/govips/v2/vips"
+)
+
+// Format 表示目标编码格式
+type Format string
+
+const (
+	FormatAVIF Format = "avif"
+	FormatWebP Format = "webp"
+)
+
+// ResizeMode 决定指定宽高时图片如何适应目标尺寸
+type ResizeMode string
+
+const (
+	// ResizeFit 等比缩放使图片完整落入目标框内，不裁剪(默认行为)
+	ResizeFit ResizeMode = "fit"
+	// ResizeFill 缩放并居中裁剪以填满目标框
+	ResizeFill ResizeMode = "fill"
+	// ResizeSmartCrop 缩放并基于显著区域裁剪以填满目标框
+	ResizeSmartCrop ResizeMode = "smart_crop"
+)
+
+// EncodeOptions 描述一次图片转换需要的全部参数
+type EncodeOptions struct {
+	Format       Format     // 目标格式: avif / webp
+	Quality      int        // 压缩质量(0-100)
+	MaxWidth     int        // 最大宽度，0表示不限制
+	MaxHeight    int        // 最大高度，0表示不限制
+	Mode         ResizeMode // 缩放策略，空值等同于ResizeFit
+	KeepOriginal bool       // 是否保留原始文件
+	UseTargetExt bool       // 输出文件是否使用目标格式的扩展名，否则保持原扩展名
+}
+
+var startOnce sync.Once
+
+// ensureStarted 保证libvips运行时只被初始化一次
+func ensureStarted() {
+	startOnce.Do(func() {
+		vips.Startup(&vips.Config{
+			ReportLeaks: false,
+		})
+	})
+}
+
+// ConvertImage 对单张图片执行"解码一次->缩放->重编码->落盘"的完整管线，
+// 返回输出文件路径。
+// 参数:
+// - srcPath: 源图片路径
+// - opts: 编码选项，参见EncodeOptions
+// 返回值:
+// - 输出图片路径
+// - 错误信息
+func ConvertImage(srcPath string, opts EncodeOptions) (string, error) {
+	ensureStarted()
+
+	if opts.Format != FormatAVIF && opts.Format != FormatWebP {
+		return "", fmt.Errorf("不支持的目标格式: %s", opts.Format)
+	}
+
+	img, err := vips.NewImageFromFile(srcPath)
+	if err != nil {
+		return "", fmt.Errorf("解码图片失败 %s: %w", srcPath, err)
+	}
+	defer img.Close()
+
+	if opts.MaxWidth > 0 || opts.MaxHeight > 0 {
+		if err := resize(img, opts); err != nil {
+			return "", fmt.Errorf("缩放图片失败 %s: %w", srcPath, err)
+		}
+	}
+
+	dstPath := targetPath(srcPath, opts)
+
+	buf, err := export(img, opts)
+	if err != nil {
+		return "", fmt.Errorf("编码图片失败 %s: %w", srcPath, err)
+	}
+
+	if err := os.WriteFile(dstPath, buf, 0644); err != nil {
+		return "", fmt.Errorf("写入输出文件失败 %s: %w", dstPath, err)
+	}
+
+	if !opts.KeepOriginal && dstPath != srcPath {
+		if err := os.Remove(srcPath); err != nil {
+			return "", fmt.Errorf("删除原始文件失败 %s: %w", srcPath, err)
+		}
+	}
+
+	return dstPath, nil
+}
+
+// resize 根据ResizeMode对图片执行等比缩放或裁剪，0表示对应方向不限制
+func resize(img *vips.ImageRef, opts EncodeOptions) error {
+	width := img.Width()
+	height := img.Height()
+
+	plan := planResize(opts.Mode, width, height, opts.MaxWidth, opts.MaxHeight)
+	if plan.targetWidth <= 0 || plan.targetHeight <= 0 {
+		return nil
+	}
+
+	if plan.crop {
+		return img.Thumbnail(plan.targetWidth, plan.targetHeight, plan.interesting)
+	}
+
+	if plan.targetWidth == width && plan.targetHeight == height {
+		return nil
+	}
+
+	scale := float64(plan.targetWidth) / float64(width)
+	return img.Resize(scale, vips.KernelLanczos3)
+}
+
+// resizePlan 描述resize应执行的操作，由planResize根据ResizeMode计算得出
+type resizePlan struct {
+	targetWidth  int
+	targetHeight int
+	crop         bool
+	interesting  vips.Interesting
+}
+
+// planResize 根据ResizeMode、源尺寸与目标宽高计算出应执行的缩放/裁剪方案。
+// 当只提供宽或高中的一个时，始终按原始宽高比推算另一边，不裁剪；
+// 当宽高都提供时，ResizeFill/ResizeSmartCrop会裁剪以填满目标框，
+// ResizeFit则继续等比缩放使图片完整落入目标框。
+func planResize(mode ResizeMode, originalWidth, originalHeight, maxWidth, maxHeight int) resizePlan {
+	if maxWidth <= 0 && maxHeight <= 0 {
+		return resizePlan{targetWidth: originalWidth, targetHeight: originalHeight}
+	}
+
+	if (mode == ResizeFill || mode == ResizeSmartCrop) && maxWidth > 0 && maxHeight > 0 {
+		interesting := vips.InterestingCentre
+		if mode == ResizeSmartCrop {
+			interesting = vips.InterestingAttention
+		}
+		return resizePlan{targetWidth: maxWidth, targetHeight: maxHeight, crop: true, interesting: interesting}
+	}
+
+	w, h := calculateTargetSize(originalWidth, originalHeight, maxWidth, maxHeight)
+	return resizePlan{targetWidth: w, targetHeight: h}
+}
+
+// calculateTargetSize 计算等比缩放后的目标尺寸
+func calculateTargetSize(originalWidth, originalHeight, maxWidth, maxHeight int) (int, int) {
+	if maxWidth <= 0 && maxHeight <= 0 {
+		return originalWidth, originalHeight
+	}
+
+	widthRatio := 1.0
+	if maxWidth > 0 {
+		widthRatio = float64(maxWidth) / float64(originalWidth)
+	}
+	heightRatio := 1.0
+	if maxHeight > 0 {
+		heightRatio = float64(maxHeight) / float64(originalHeight)
+	}
+
+	scaleRatio := widthRatio
+	if maxWidth <= 0 || (maxHeight > 0 && heightRatio < widthRatio) {
+		scaleRatio = heightRatio
+	}
+
+	if scaleRatio > 1.0 {
+		scaleRatio = 1.0
+	}
+
+	newWidth := int(float64(originalWidth) * scaleRatio)
+	newHeight := int(float64(originalHeight) * scaleRatio)
+
+	return newWidth, newHeight
+}
+
+// export 将图片编码为目标格式的字节流
+func export(img *vips.ImageRef, opts EncodeOptions) ([]byte, error) {
+	switch opts.Format {
+	case FormatAVIF:
+		buf, _, err := img.ExportAvif(&vips.AvifExportParams{
+			Quality: opts.Quality,
+		})
+		return buf, err
+	case FormatWebP:
+		buf, _, err := img.ExportWebp(&vips.WebpExportParams{
+			Quality: opts.Quality,
+		})
+		return buf, err
+	default:
+		return nil, fmt.Errorf("不支持的目标格式: %s", opts.Format)
+	}
+}
+
+// targetPath 根据UseTargetExt决定输出文件名是否替换为目标格式的扩展名
+func targetPath(srcPath string, opts EncodeOptions) string {
+	if !opts.UseTargetExt {
+		return srcPath
+	}
+
+	ext := "." + string(opts.Format)
+	base := strings.TrimSuffix(srcPath, filepath.Ext(srcPath))
+	return base + ext
+}
@@ -0,0 +1,104 @@
+package imgenc
+
+import (
+	"testing"
+
+	"github.com/davidbyttow/govips/v2/vips"
+)
+
+func TestPlanResizeFitSingleDimension(t *testing.T) {
+	cases := []struct {
+		name                          string
+		originalWidth, originalHeight int
+		maxWidth, maxHeight           int
+		wantWidth, wantHeight         int
+	}{
+		{"landscape width only", 1920, 1080, 960, 0, 960, 540},
+		{"landscape height only", 1920, 1080, 0, 540, 960, 540},
+		{"portrait width only", 1080, 1920, 540, 0, 540, 960},
+		{"portrait height only", 1080, 1920, 0, 960, 540, 960},
+		{"square width only", 1000, 1000, 500, 0, 500, 500},
+		{"square height only", 1000, 1000, 0, 500, 500, 500},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			plan := planResize(ResizeFit, tc.originalWidth, tc.originalHeight, tc.maxWidth, tc.maxHeight)
+			if plan.crop {
+				t.Fatalf("expected no crop, got crop=true")
+			}
+			if plan.targetWidth != tc.wantWidth || plan.targetHeight != tc.wantHeight {
+				t.Errorf("got %dx%d, want %dx%d", plan.targetWidth, plan.targetHeight, tc.wantWidth, tc.wantHeight)
+			}
+		})
+	}
+}
+
+func TestPlanResizeFitBothDimensionsNoCrop(t *testing.T) {
+	cases := []struct {
+		name                          string
+		originalWidth, originalHeight int
+		maxWidth, maxHeight           int
+	}{
+		{"landscape", 1920, 1080, 800, 800},
+		{"portrait", 1080, 1920, 800, 800},
+		{"square", 1000, 1000, 400, 400},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			plan := planResize(ResizeFit, tc.originalWidth, tc.originalHeight, tc.maxWidth, tc.maxHeight)
+			if plan.crop {
+				t.Fatalf("ResizeFit must never crop")
+			}
+			if plan.targetWidth > tc.maxWidth || plan.targetHeight > tc.maxHeight {
+				t.Errorf("target %dx%d exceeds box %dx%d", plan.targetWidth, plan.targetHeight, tc.maxWidth, tc.maxHeight)
+			}
+		})
+	}
+}
+
+func TestPlanResizeFillAndSmartCropBothDimensions(t *testing.T) {
+	shapes := []struct {
+		name                          string
+		originalWidth, originalHeight int
+	}{
+		{"landscape", 1920, 1080},
+		{"portrait", 1080, 1920},
+		{"square", 1000, 1000},
+	}
+	modes := []struct {
+		mode        ResizeMode
+		interesting vips.Interesting
+	}{
+		{ResizeFill, vips.InterestingCentre},
+		{ResizeSmartCrop, vips.InterestingAttention},
+	}
+
+	for _, shape := range shapes {
+		for _, m := range modes {
+			t.Run(shape.name+"_"+string(m.mode), func(t *testing.T) {
+				plan := planResize(m.mode, shape.originalWidth, shape.originalHeight, 400, 400)
+				if !plan.crop {
+					t.Fatalf("expected crop for mode %s", m.mode)
+				}
+				if plan.targetWidth != 400 || plan.targetHeight != 400 {
+					t.Errorf("got %dx%d, want 400x400", plan.targetWidth, plan.targetHeight)
+				}
+				if plan.interesting != m.interesting {
+					t.Errorf("got interesting=%v, want %v", plan.interesting, m.interesting)
+				}
+			})
+		}
+	}
+}
+
+func TestPlanResizeFillSingleDimensionFallsBackToFit(t *testing.T) {
+	plan := planResize(ResizeFill, 1920, 1080, 960, 0)
+	if plan.crop {
+		t.Fatalf("single dimension must not crop even in ResizeFill mode")
+	}
+	if plan.targetWidth != 960 || plan.targetHeight != 540 {
+		t.Errorf("got %dx%d, want 960x540", plan.targetWidth, plan.targetHeight)
+	}
+}
@@ -0,0 +1,45 @@
+package utils
+
+import (
+	"errors"
+	"fmt"
+	"image"
+	_ "image/gif"
+	_ "image/jpeg"
+	_ "image/png"
+	"os"
+
+	_ "golang.org/x/image/webp"
+
+	"github.com/aspnmy/GoComicMosaic-gobackend/internal/config"
+)
+
+// ErrResolutionExceeded 表示图片解码后的分辨率超过了config.ResolutionLimit设置的上限
+var ErrResolutionExceeded = errors.New("图片分辨率超出限制")
+
+// CheckImageResolution 仅解析图片文件头获取宽高(不解码像素数据)，
+// 并与config.ResolutionLimit(单位: 百万像素)比较，用于在解码/转换前拦截解压缩炸弹。
+// 参数:
+// - path: 图片文件路径
+// 返回值:
+// - 图片宽度、高度
+// - 错误信息，超出分辨率上限时为ErrResolutionExceeded
+func CheckImageResolution(path string) (int, int, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return 0, 0, fmt.Errorf("打开图片失败 %s: %w", path, err)
+	}
+	defer f.Close()
+
+	cfg, _, err := image.DecodeConfig(f)
+	if err != nil {
+		return 0, 0, fmt.Errorf("解析图片头信息失败 %s: %w", path, err)
+	}
+
+	megapixels := float64(cfg.Width) * float64(cfg.Height) / 1_000_000
+	if megapixels > config.GetResolutionLimit() {
+		return cfg.Width, cfg.Height, ErrResolutionExceeded
+	}
+
+	return cfg.Width, cfg.Height, nil
+}
@@ -0,0 +1,107 @@
+package utils
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/aspnmy/GoComicMosaic-gobackend/internal/config"
+	"github.com/aspnmy/GoComicMosaic-gobackend/internal/utils/imgenc"
+)
+
+// IsVideoFile 判断文件名的扩展名是否为受支持的视频格式(config.VideoExtensions)
+func IsVideoFile(filename string) bool {
+	ext := strings.ToLower(filepath.Ext(filename))
+	for _, v := range config.VideoExtensions {
+		if ext == v {
+			return true
+		}
+	}
+	return false
+}
+
+// SaveVideoUpload 校验并保存一次视频上传到AssetsDir/uploads
+// 参数:
+// - filename: 原始文件名，用于判断扩展名与生成目标文件名
+// - size: 上传数据大小(字节)，用于与config.VideoMaxSizeMB比较
+// - src: 上传数据流
+// 返回值:
+// - 保存后的文件路径
+// - 错误信息
+func SaveVideoUpload(filename string, size int64, src io.Reader) (string, error) {
+	if !config.VideoEnabled {
+		return "", fmt.Errorf("视频上传功能未启用")
+	}
+	if !IsVideoFile(filename) {
+		return "", fmt.Errorf("不支持的视频格式: %s", filepath.Ext(filename))
+	}
+
+	maxBytes := int64(config.VideoMaxSizeMB) * 1024 * 1024
+	if size > maxBytes {
+		return "", fmt.Errorf("视频文件超出大小限制(%dMB)", config.VideoMaxSizeMB)
+	}
+
+	dstPath := filepath.Join(config.GetAssetsDir(), "uploads", filepath.Base(filename))
+	dst, err := os.Create(dstPath)
+	if err != nil {
+		return "", fmt.Errorf("创建上传文件失败 %s: %w", dstPath, err)
+	}
+	defer dst.Close()
+
+	if _, err := io.Copy(dst, src); err != nil {
+		return "", fmt.Errorf("写入上传文件失败 %s: %w", dstPath, err)
+	}
+
+	return dstPath, nil
+}
+
+// ExtractPoster 使用ffmpeg从视频中截取一帧作为封面，并通过现有图片编码管线
+// 生成webp/avif缩略图，落盘到AssetsDir/imgs下。
+// 参数:
+// - videoPath: 视频文件路径
+// - atSeconds: 截取时间点(秒)
+// 返回值:
+// - 封面缩略图路径
+// - 错误信息
+func ExtractPoster(videoPath string, atSeconds float64) (string, error) {
+	if !config.VideoEnabled {
+		return "", fmt.Errorf("视频上传功能未启用")
+	}
+
+	framePath := filepath.Join(config.GetAssetsDir(), "imgs", posterFrameName(videoPath))
+
+	cmd := exec.Command("ffmpeg",
+		"-y",
+		"-ss", strconv.FormatFloat(atSeconds, 'f', -1, 64),
+		"-i", videoPath,
+		"-frames:v", "1",
+		framePath,
+	)
+	if output, err := cmd.CombinedOutput(); err != nil {
+		return "", fmt.Errorf("ffmpeg截取封面帧失败: %w: %s", err, output)
+	}
+
+	posterPath, err := convertWithBackend(framePath, imgenc.EncodeOptions{
+		Format:       imgenc.Format(config.GetImageFormat()),
+		Quality:      config.GetImageQuality(),
+		MaxWidth:     config.GetMaxWidth(),
+		MaxHeight:    config.GetMaxHeight(),
+		KeepOriginal: false,
+		UseTargetExt: true,
+	})
+	if err != nil {
+		return "", fmt.Errorf("生成视频封面缩略图失败: %w", err)
+	}
+
+	return posterPath, nil
+}
+
+// posterFrameName 根据视频文件名生成ffmpeg截帧的临时输出文件名
+func posterFrameName(videoPath string) string {
+	base := strings.TrimSuffix(filepath.Base(videoPath), filepath.Ext(videoPath))
+	return base + "_poster.jpg"
+}